@@ -0,0 +1,241 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetworkfibtrie
+// +build !nonetworkfibtrie
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	fibTrieStatPath = "/proc/net/fib_triestat"
+	fibTriePath     = "/proc/net/fib_trie"
+	ipv6RoutePath   = "/proc/net/ipv6_route"
+)
+
+type networkFibTrieCollector struct {
+	nodesDesc     *prometheus.Desc
+	leavesDesc    *prometheus.Desc
+	prefixesDesc  *prometheus.Desc
+	sizeBytesDesc *prometheus.Desc
+	logger        log.Logger
+}
+
+func init() {
+	registerCollector("network_fib_trie", defaultDisabled, NewNetworkFibTrieCollector)
+}
+
+// NewNetworkFibTrieCollector returns a new Collector exposing kernel FIB trie
+// statistics. Unlike network_route, cardinality is O(1) in the number of
+// routes: it reports the trie's own node/leaf/prefix counts rather than one
+// series per route.
+func NewNetworkFibTrieCollector(logger log.Logger) (Collector, error) {
+	const subsystem = "network"
+
+	return &networkFibTrieCollector{
+		nodesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "fib_trie_nodes"),
+			"Number of FIB trie nodes by type", []string{"table", "type"}, nil,
+		),
+		leavesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "fib_trie_leaves"),
+			"Number of FIB trie leaves", []string{"table"}, nil,
+		),
+		prefixesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "fib_trie_prefixes"),
+			"Number of FIB trie prefixes", []string{"table", "family"}, nil,
+		),
+		sizeBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "fib_trie_size_bytes"),
+			"Size of the FIB trie in bytes", []string{"table"}, nil,
+		),
+		logger: logger,
+	}, nil
+}
+
+func (c *networkFibTrieCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := fibTrieStatsParse(fibTrieStatPath)
+	if os.IsNotExist(err) {
+		stats, err = fibTrieParseRaw(fibTriePath)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't get fib trie stats: %w", err)
+	}
+
+	for table, s := range stats {
+		ch <- prometheus.MustNewConstMetric(c.nodesDesc, prometheus.GaugeValue, float64(s.internalNodes), table, "internal")
+		ch <- prometheus.MustNewConstMetric(c.nodesDesc, prometheus.GaugeValue, float64(s.pointers), table, "pointer")
+		ch <- prometheus.MustNewConstMetric(c.leavesDesc, prometheus.GaugeValue, float64(s.leaves), table)
+		ch <- prometheus.MustNewConstMetric(c.prefixesDesc, prometheus.GaugeValue, float64(s.prefixes), table, "IPv4")
+		ch <- prometheus.MustNewConstMetric(c.sizeBytesDesc, prometheus.GaugeValue, float64(s.sizeBytes), table)
+	}
+
+	v6Prefixes, err := ipv6RoutePrefixCount(ipv6RoutePath)
+	if err != nil {
+		return fmt.Errorf("couldn't get ipv6 route count: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.prefixesDesc, prometheus.GaugeValue, float64(v6Prefixes), "main", "IPv6")
+
+	return nil
+}
+
+type fibTrieTableStats struct {
+	internalNodes int
+	leaves        int
+	prefixes      int
+	pointers      int
+	sizeBytes     int
+}
+
+var (
+	fibTrieStatTableRE     = regexp.MustCompile(`^(\S+):$`)
+	fibTrieStatLeavesRE    = regexp.MustCompile(`^\s*Leaves:\s*(\d+)`)
+	fibTrieStatPrefixesRE  = regexp.MustCompile(`^\s*Prefixes:\s*(\d+)`)
+	fibTrieStatInternalRE  = regexp.MustCompile(`^\s*Internal nodes:\s*(\d+)`)
+	fibTrieStatPointersRE  = regexp.MustCompile(`^\s*Pointers:\s*(\d+)`)
+	fibTrieStatTotalSizeRE = regexp.MustCompile(`^\s*Total size:\s*(\d+)\s*kB`)
+)
+
+// fibTrieStatsParse parses /proc/net/fib_triestat, which reports per-table
+// IPv4 FIB trie summary statistics (one "Main:"/"Local:"/<vrf-name>: section
+// per routing table).
+func fibTrieStatsParse(path string) (map[string]*fibTrieTableStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tableNames := networkRouteBaseTableMap()
+	stats := map[string]*fibTrieTableStats{}
+	var current *fibTrieTableStats
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := fibTrieStatTableRE.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			name := m[1]
+			if id, err := strconv.Atoi(name); err == nil {
+				if resolved, ok := tableNames[id]; ok {
+					name = resolved
+				}
+			}
+			name = strings.ToLower(name)
+			current = &fibTrieTableStats{}
+			stats[name] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if m := fibTrieStatLeavesRE.FindStringSubmatch(line); m != nil {
+			current.leaves, _ = strconv.Atoi(m[1])
+		} else if m := fibTrieStatPrefixesRE.FindStringSubmatch(line); m != nil {
+			current.prefixes, _ = strconv.Atoi(m[1])
+		} else if m := fibTrieStatInternalRE.FindStringSubmatch(line); m != nil {
+			current.internalNodes, _ = strconv.Atoi(m[1])
+		} else if m := fibTrieStatPointersRE.FindStringSubmatch(line); m != nil {
+			current.pointers, _ = strconv.Atoi(m[1])
+		} else if m := fibTrieStatTotalSizeRE.FindStringSubmatch(line); m != nil {
+			kb, _ := strconv.Atoi(m[1])
+			current.sizeBytes = kb * 1024
+		}
+	}
+
+	return stats, scanner.Err()
+}
+
+var (
+	fibTrieTableHeaderRE  = regexp.MustCompile(`^(\S+):$`)
+	fibTrieInternalLineRE = regexp.MustCompile(`^\s*\+--\s`)
+	fibTrieLeafLineRE     = regexp.MustCompile(`^\s*\|--\s`)
+	fibTriePrefixLineRE   = regexp.MustCompile(`^\s*/\d+\s`)
+)
+
+// fibTrieParseRaw is a best-effort fallback used when fib_triestat isn't
+// available (e.g. CONFIG_IP_FIB_TRIE_STATS is unset): it counts trie node
+// and prefix lines directly out of the /proc/net/fib_trie tree dump rather
+// than reading pre-computed statistics.
+func fibTrieParseRaw(path string) (map[string]*fibTrieTableStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stats := map[string]*fibTrieTableStats{}
+	var current *fibTrieTableStats
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := fibTrieTableHeaderRE.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			current = &fibTrieTableStats{}
+			stats[strings.ToLower(m[1])] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch {
+		case fibTrieInternalLineRE.MatchString(line):
+			current.internalNodes++
+		case fibTrieLeafLineRE.MatchString(line):
+			current.leaves++
+		case fibTriePrefixLineRE.MatchString(line):
+			current.prefixes++
+		}
+	}
+
+	return stats, scanner.Err()
+}
+
+// ipv6RoutePrefixCount returns the number of routes in /proc/net/ipv6_route.
+// The file carries no per-table column, so all routes are reported under
+// the "main" table as an O(1)-cardinality approximation of IPv6 FIB size.
+func ipv6RoutePrefixCount(path string) (int, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		count++
+	}
+
+	return count, scanner.Err()
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetworkfibtrie
+// +build !nonetworkfibtrie
+
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFibTrieStatsParse(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fib_triestat")
+	content := "Basic info: size of leaf: 64 bytes, size of tnode: 88 bytes.\n" +
+		"Main:\n" +
+		"\tAver depth:     2\n" +
+		"\tMax depth:      3\n" +
+		"\tLeaves:         5\n" +
+		"\tPrefixes:       6\n" +
+		"\tInternal nodes: 3\n" +
+		"\tPointers:       8\n" +
+		"Null ptr bits:  0\n" +
+		"Total size:     1 kB\n" +
+		"Local:\n" +
+		"\tAver depth:     1\n" +
+		"\tMax depth:      1\n" +
+		"\tLeaves:         2\n" +
+		"\tPrefixes:       2\n" +
+		"\tInternal nodes: 1\n" +
+		"\tPointers:       1\n" +
+		"Null ptr bits:  0\n" +
+		"Total size:     1 kB\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fibTrieStatsParse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*fibTrieTableStats{
+		"main":  {internalNodes: 3, leaves: 5, prefixes: 6, pointers: 8, sizeBytes: 1024},
+		"local": {internalNodes: 1, leaves: 2, prefixes: 2, pointers: 1, sizeBytes: 1024},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFibTrieStatsParseMissingFile(t *testing.T) {
+	if _, err := fibTrieStatsParse(filepath.Join(t.TempDir(), "does-not-exist")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestFibTrieParseRaw(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fib_trie")
+	content := "Main:\n" +
+		"  +-- 0.0.0.0/0 3 0 5\n" +
+		"     |-- 0.0.0.0\n" +
+		"        /0 universe UNICAST\n" +
+		"     +-- 127.0.0.0/8 2 0 2\n" +
+		"        |-- 127.0.0.1\n" +
+		"           /32 host LOCAL\n" +
+		"Local:\n" +
+		"  +-- 0.0.0.0/0 1 0 1\n" +
+		"     |-- 127.0.0.1\n" +
+		"        /32 host LOCAL\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fibTrieParseRaw(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*fibTrieTableStats{
+		"main":  {internalNodes: 2, leaves: 2, prefixes: 2},
+		"local": {internalNodes: 1, leaves: 1, prefixes: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestIPv6RoutePrefixCount(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ipv6_route")
+	content := "00000000000000000000000000000000 00 00000000000000000000000000000000 00 " +
+		"00000000000000000000000000000000 00000000 00000001 00000000 00000000 lo\n" +
+		"20010db8000000000000000000000000 40 00000000000000000000000000000000 00 " +
+		"00000000000000000000000000000000 00000000 00000001 00000000 00000000 eth0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ipv6RoutePrefixCount(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 {
+		t.Errorf("got %d, want 2", got)
+	}
+}
+
+func TestIPv6RoutePrefixCountMissingFile(t *testing.T) {
+	got, err := ipv6RoutePrefixCount(filepath.Join(t.TempDir(), "does-not-exist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 0 {
+		t.Errorf("got %d, want 0 for a missing file", got)
+	}
+}
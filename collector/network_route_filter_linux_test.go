@@ -0,0 +1,210 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetworkroute
+// +build !nonetworkroute
+
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+func TestNetworkRouteParseTables(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		wantNames []string
+		wantAll   bool
+	}{
+		{"empty means all", "", nil, true},
+		{"all keyword", "all", nil, true},
+		{"all keyword case insensitive", "ALL", nil, true},
+		{"single table", "main", []string{"main"}, false},
+		{"multiple tables trimmed", " main, local ,default", []string{"main", "local", "default"}, false},
+		{"blank entries dropped", "main,,local", []string{"main", "local"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, all := networkRouteParseTables(tt.in)
+			if all != tt.wantAll {
+				t.Errorf("all = %v, want %v", all, tt.wantAll)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestNetworkRouteResolveTableIDs(t *testing.T) {
+	routingTableMaps := map[int]string{
+		253: "default",
+		254: "main",
+		255: "local",
+		100: "vrf-blue",
+	}
+
+	tests := []struct {
+		name  string
+		names []string
+		want  []int
+	}{
+		{"resolve by name", []string{"main", "local"}, []int{254, 255}},
+		{"resolve vrf name", []string{"vrf-blue"}, []int{100}},
+		{"fall back to literal id", []string{"42"}, []int{42}},
+		{"unknown non-numeric name is dropped", []string{"bogus"}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := networkRouteResolveTableIDs(tt.names, routingTableMaps)
+			sort.Ints(got)
+			sort.Ints(tt.want)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkRouteParseProtocols(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		wantInclude map[string]bool
+		wantExclude map[string]bool
+	}{
+		{"empty means no filter", "", nil, nil},
+		{"single include", "static", map[string]bool{"static": true}, map[string]bool{}},
+		{"include and exclude", "static,!kernel", map[string]bool{"static": true}, map[string]bool{"kernel": true}},
+		{"blank entries dropped", "static,,!kernel", map[string]bool{"static": true}, map[string]bool{"kernel": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			include, exclude := networkRouteParseProtocols(tt.in)
+			if !reflect.DeepEqual(include, tt.wantInclude) {
+				t.Errorf("include = %v, want %v", include, tt.wantInclude)
+			}
+			if !reflect.DeepEqual(exclude, tt.wantExclude) {
+				t.Errorf("exclude = %v, want %v", exclude, tt.wantExclude)
+			}
+		})
+	}
+}
+
+func TestNetworkRouteSingleProtocolFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		include map[string]bool
+		exclude map[string]bool
+		want    *uint8
+	}{
+		{"no filters", nil, nil, nil},
+		{"single known include", map[string]bool{"static": true}, map[string]bool{}, uint8Ptr(4)},
+		{"single unknown include", map[string]bool{"bogus": true}, map[string]bool{}, nil},
+		{"multiple includes can't push down", map[string]bool{"static": true, "kernel": true}, map[string]bool{}, nil},
+		{"any exclude can't push down", map[string]bool{"static": true}, map[string]bool{"kernel": true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := networkRouteSingleProtocolFilter(tt.include, tt.exclude)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func uint8Ptr(v uint8) *uint8 { return &v }
+
+func TestNetworkRouteProtocolAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		proto   uint8
+		include map[string]bool
+		exclude map[string]bool
+		want    bool
+	}{
+		{"no filters allows everything", 4, nil, nil, true},
+		{"included protocol allowed", 4, map[string]bool{"static": true}, map[string]bool{}, true},
+		{"non-included protocol denied", 2, map[string]bool{"static": true}, map[string]bool{}, false},
+		{"excluded protocol denied", 2, map[string]bool{}, map[string]bool{"kernel": true}, false},
+		{"non-excluded protocol allowed", 4, map[string]bool{}, map[string]bool{"kernel": true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := networkRouteProtocolAllowed(tt.proto, tt.include, tt.exclude); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkRouteDeviceAllowed(t *testing.T) {
+	eth0 := regexp.MustCompile(`^eth0$`)
+	veth := regexp.MustCompile(`^veth`)
+
+	tests := []struct {
+		name    string
+		device  string
+		include *regexp.Regexp
+		exclude *regexp.Regexp
+		want    bool
+	}{
+		{"no filters allows everything", "eth0", nil, nil, true},
+		{"matches include", "eth0", eth0, nil, true},
+		{"doesn't match include", "eth1", eth0, nil, false},
+		{"matches exclude", "veth123", nil, veth, false},
+		{"doesn't match exclude", "eth0", nil, veth, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := networkRouteDeviceAllowed(tt.device, tt.include, tt.exclude); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkRouteParseRTTables(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rt_tables")
+	content := "# reserved values\n255\tlocal\n254\tmain\n253\tdefault\n0\tunspec\n\n# custom\n100\tvrf-blue\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]string{
+		255: "local",
+		254: "main",
+		253: "default",
+		0:   "unspec",
+		100: "vrf-blue",
+	}
+	got := networkRouteParseRTTables(path)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNetworkRouteParseRTTablesMissingFile(t *testing.T) {
+	got := networkRouteParseRTTables(filepath.Join(t.TempDir(), "does-not-exist"))
+	if len(got) != 0 {
+		t.Errorf("expected empty map for missing file, got %v", got)
+	}
+}
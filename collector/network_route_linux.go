@@ -17,20 +17,64 @@
 package collector
 
 import (
+	"bufio"
+	"encoding/binary"
 	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unsafe"
+
 	"golang.org/x/sys/unix"
 	"net"
-	"strconv"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
-	"github.com/vishvananda/netlink"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+)
+
+// rtTablesPath is the iproute2 table-name database consulted in addition to
+// the kernel defaults and any VRF-derived tables discovered at runtime.
+const rtTablesPath = "/etc/iproute2/rt_tables"
+
+// clockTicksPerSecond is USER_HZ, the unit rta_cacheinfo's lastuse/expires
+// fields are reported in. It is fixed at 100 on every architecture Linux
+// currently supports.
+const clockTicksPerSecond = 100
+
+var (
+	networkRouteTables = kingpin.Flag("collector.network_route.tables",
+		"Comma separated list of routing tables to collect, or 'all' to collect every table.").
+		Default("main,local,default").String()
+	networkRouteProtocols = kingpin.Flag("collector.network_route.protocols",
+		"Comma separated list of routing protocols to collect. Prefix a protocol with '!' to exclude it. Default is all protocols.").
+		Default("").String()
+	networkRouteDeviceInclude = kingpin.Flag("collector.network_route.device-include",
+		"Regexp of devices to include (mutually exclusive with device-exclude).").String()
+	networkRouteDeviceExclude = kingpin.Flag("collector.network_route.device-exclude",
+		"Regexp of devices to exclude (mutually exclusive with device-include).").String()
+	networkRouteCacheInfo = kingpin.Flag("collector.network_route.cache-info",
+		"Collect route cache last-use/expiry/error information (requires an extra raw netlink dump per scrape).").
+		Default("false").Bool()
 )
 
 type networkRouteCollector struct {
-	routeInfoDesc *prometheus.Desc
-	routesDesc    *prometheus.Desc
-	logger        log.Logger
+	routeInfoDesc    *prometheus.Desc
+	routesDesc       *prometheus.Desc
+	routeExpiresDesc *prometheus.Desc
+	routeLastUseDesc *prometheus.Desc
+	routeErrorDesc   *prometheus.Desc
+	logger           log.Logger
+
+	tableNames      []string
+	allTables       bool
+	protocolInclude map[string]bool
+	protocolExclude map[string]bool
+	deviceInclude   *regexp.Regexp
+	deviceExclude   *regexp.Regexp
+	cacheInfo       bool
 }
 
 func init() {
@@ -43,17 +87,59 @@ func NewNetworkRouteCollector(logger log.Logger) (Collector, error) {
 
 	routeInfoDesc := prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, subsystem, "route_info"),
-		"network routing table information", []string{"device", "src", "dest", "gw", "priority", "proto", "weight", "family", "table"}, nil,
+		"network routing table information", []string{"device", "src", "dest", "gw", "priority", "proto", "weight", "family", "table", "encap_type", "encap_dst", "mpls_labels"}, nil,
 	)
 	routesDesc := prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, subsystem, "routes"),
 		"network routes by interface", []string{"device"}, nil,
 	)
+	routeExpiresDesc := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "route_expires_seconds"),
+		"Time until a cached route expires, or 0 if it doesn't", []string{"device", "dest", "table", "family"}, nil,
+	)
+	routeLastUseDesc := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "route_last_used_seconds"),
+		"Time since a cached route was last used", []string{"device", "dest", "table", "family"}, nil,
+	)
+	routeErrorDesc := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "route_error_total"),
+		"Error code recorded against a cached route, or 0 if none", []string{"device", "dest", "table", "family"}, nil,
+	)
+
+	tableNames, allTables := networkRouteParseTables(*networkRouteTables)
+	protocolInclude, protocolExclude := networkRouteParseProtocols(*networkRouteProtocols)
+
+	if *networkRouteDeviceInclude != "" && *networkRouteDeviceExclude != "" {
+		return nil, fmt.Errorf("collector.network_route.device-include and collector.network_route.device-exclude are mutually exclusive")
+	}
+
+	var deviceInclude, deviceExclude *regexp.Regexp
+	var err error
+	if *networkRouteDeviceInclude != "" {
+		if deviceInclude, err = regexp.Compile(*networkRouteDeviceInclude); err != nil {
+			return nil, fmt.Errorf("invalid collector.network_route.device-include regexp: %w", err)
+		}
+	}
+	if *networkRouteDeviceExclude != "" {
+		if deviceExclude, err = regexp.Compile(*networkRouteDeviceExclude); err != nil {
+			return nil, fmt.Errorf("invalid collector.network_route.device-exclude regexp: %w", err)
+		}
+	}
 
 	return &networkRouteCollector{
-		routeInfoDesc: routeInfoDesc,
-		routesDesc:    routesDesc,
-		logger:        logger,
+		routeInfoDesc:    routeInfoDesc,
+		routesDesc:       routesDesc,
+		routeExpiresDesc: routeExpiresDesc,
+		routeLastUseDesc: routeLastUseDesc,
+		routeErrorDesc:   routeErrorDesc,
+		logger:           logger,
+		tableNames:       tableNames,
+		allTables:        allTables,
+		protocolInclude:  protocolInclude,
+		protocolExclude:  protocolExclude,
+		deviceInclude:    deviceInclude,
+		deviceExclude:    deviceExclude,
+		cacheInfo:        *networkRouteCacheInfo,
 	}, nil
 }
 
@@ -67,7 +153,10 @@ func (n networkRouteCollector) Update(ch chan<- prometheus.Metric) error {
 
 	routingTableMaps := networkRouteGenerateRoutingTableMap(links)
 
-	routesByFamily, err := networkRouteGet()
+	tableIDs := networkRouteResolveTableIDs(n.tableNames, routingTableMaps)
+	protoFilter := networkRouteSingleProtocolFilter(n.protocolInclude, n.protocolExclude)
+
+	routesByFamily, err := networkRouteGet(tableIDs, n.allTables, protoFilter)
 	if err != nil {
 		return fmt.Errorf("couldn't get routes: %w", err)
 	}
@@ -77,6 +166,9 @@ func (n networkRouteCollector) Update(ch chan<- prometheus.Metric) error {
 			if route.Type != unix.RTA_DST {
 				continue
 			}
+			if !networkRouteProtocolAllowed(uint8(route.Protocol), n.protocolInclude, n.protocolExclude) {
+				continue
+			}
 			if len(route.MultiPath) != 0 {
 				for _, nextHop := range route.MultiPath {
 					ifName := ""
@@ -86,6 +178,10 @@ func (n networkRouteCollector) Update(ch chan<- prometheus.Metric) error {
 							break
 						}
 					}
+					if !networkRouteDeviceAllowed(ifName, n.deviceInclude, n.deviceExclude) {
+						continue
+					}
+					encapType, encapDst, mplsLabels := networkRouteEncapInfo(nextHop.Encap, nextHop.NewDst)
 
 					labels := []string{
 						ifName,                                              // if
@@ -97,6 +193,9 @@ func (n networkRouteCollector) Update(ch chan<- prometheus.Metric) error {
 						strconv.Itoa(int(nextHop.Hops) + 1),                 // weight
 						family,                                              // Family
 						routingTableMaps[route.Table],                       // Table
+						encapType,                                           // encap_type
+						encapDst,                                            // encap_dst
+						mplsLabels,                                          // mpls_labels
 					}
 					ch <- prometheus.MustNewConstMetric(n.routeInfoDesc, prometheus.GaugeValue, 1, labels...)
 					deviceRoutes[ifName]++
@@ -109,6 +208,10 @@ func (n networkRouteCollector) Update(ch chan<- prometheus.Metric) error {
 						break
 					}
 				}
+				if !networkRouteDeviceAllowed(ifName, n.deviceInclude, n.deviceExclude) {
+					continue
+				}
+				encapType, encapDst, mplsLabels := networkRouteEncapInfo(route.Encap, route.NewDst)
 
 				labels := []string{
 					ifName,                                              // if
@@ -120,6 +223,9 @@ func (n networkRouteCollector) Update(ch chan<- prometheus.Metric) error {
 					"",                            // weight
 					family,                        // Family
 					routingTableMaps[route.Table], // Table
+					encapType,                     // encap_type
+					encapDst,                      // encap_dst
+					mplsLabels,                    // mpls_labels
 				}
 				ch <- prometheus.MustNewConstMetric(n.routeInfoDesc, prometheus.GaugeValue, 1, labels...)
 				deviceRoutes[ifName]++
@@ -130,30 +236,174 @@ func (n networkRouteCollector) Update(ch chan<- prometheus.Metric) error {
 		ch <- prometheus.MustNewConstMetric(n.routesDesc, prometheus.GaugeValue, float64(total), dev)
 	}
 
+	if n.cacheInfo {
+		entries, err := networkRouteCacheInfoGet(links, routingTableMaps)
+		if err != nil {
+			return fmt.Errorf("couldn't get route cache info: %w", err)
+		}
+		for _, entry := range entries {
+			ch <- prometheus.MustNewConstMetric(n.routeExpiresDesc, prometheus.GaugeValue, entry.expires, entry.device, entry.dest, entry.table, entry.family)
+			ch <- prometheus.MustNewConstMetric(n.routeLastUseDesc, prometheus.GaugeValue, entry.lastUse, entry.device, entry.dest, entry.table, entry.family)
+			ch <- prometheus.MustNewConstMetric(n.routeErrorDesc, prometheus.GaugeValue, entry.errno, entry.device, entry.dest, entry.table, entry.family)
+		}
+	}
+
 	return nil
 }
 
-func networkRouteGet() (map[string][]netlink.Route, error) {
-	routeFilter := &netlink.Route{
-		Table: 0,
+// networkRouteGet fetches IPv4 and IPv6 routes, letting the kernel do the
+// filtering via RT_FILTER_TABLE / RT_FILTER_PROTOCOL whenever the configured
+// table/protocol filters resolve to something the netlink filter mask can
+// express. Anything it can't express (multiple protocols, exclusions, ...)
+// is left for the caller to post-filter.
+func networkRouteGet(tableIDs []int, allTables bool, protoFilter *uint8) (map[string][]netlink.Route, error) {
+	families := map[int]string{
+		netlink.FAMILY_V4: "IPv4",
+		netlink.FAMILY_V6: "IPv6",
 	}
 
-	v4Routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, routeFilter, netlink.RT_FILTER_TABLE)
-	if err != nil {
-		return nil, err
+	routes := make(map[string][]netlink.Route, len(families))
+
+	for family, name := range families {
+		var familyRoutes []netlink.Route
+
+		if allTables {
+			filter := &netlink.Route{}
+			var mask uint64
+			if protoFilter != nil {
+				filter.Protocol = netlink.RouteProtocol(*protoFilter)
+				mask |= netlink.RT_FILTER_PROTOCOL
+			}
+			list, err := netlink.RouteListFiltered(family, filter, mask)
+			if err != nil {
+				return nil, err
+			}
+			familyRoutes = list
+		} else {
+			for _, tableID := range tableIDs {
+				filter := &netlink.Route{Table: tableID}
+				mask := uint64(netlink.RT_FILTER_TABLE)
+				if protoFilter != nil {
+					filter.Protocol = netlink.RouteProtocol(*protoFilter)
+					mask |= netlink.RT_FILTER_PROTOCOL
+				}
+				list, err := netlink.RouteListFiltered(family, filter, mask)
+				if err != nil {
+					return nil, err
+				}
+				familyRoutes = append(familyRoutes, list...)
+			}
+		}
+
+		routes[name] = familyRoutes
 	}
 
-	v6Routes, err := netlink.RouteListFiltered(netlink.FAMILY_V6, routeFilter, netlink.RT_FILTER_TABLE)
-	if err != nil {
-		return nil, err
+	return routes, nil
+}
+
+// networkRouteParseTables splits the --collector.network_route.tables value
+// into the table names to collect, or reports that every table should be
+// collected when the value is empty or "all".
+func networkRouteParseTables(s string) (names []string, all bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "all") {
+		return nil, true
+	}
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
 	}
+	return names, false
+}
 
-	routes := map[string][]netlink.Route{
-		"IPv4": v4Routes,
-		"IPv6": v6Routes,
+// networkRouteResolveTableIDs resolves configured table names to their
+// kernel table IDs using the routing table map, falling back to treating the
+// name as a literal table ID.
+func networkRouteResolveTableIDs(names []string, routingTableMaps map[int]string) []int {
+	nameToID := make(map[string]int, len(routingTableMaps))
+	for id, name := range routingTableMaps {
+		nameToID[name] = id
 	}
 
-	return routes, nil
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		if id, ok := nameToID[name]; ok {
+			ids = append(ids, id)
+			continue
+		}
+		if id, err := strconv.Atoi(name); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// networkRouteParseProtocols splits the --collector.network_route.protocols
+// value into protocols to include and protocols to exclude (prefixed with
+// "!"). An empty value collects every protocol.
+func networkRouteParseProtocols(s string) (include, exclude map[string]bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	include = map[string]bool{}
+	exclude = map[string]bool{}
+	for _, proto := range strings.Split(s, ",") {
+		proto = strings.TrimSpace(proto)
+		if proto == "" {
+			continue
+		}
+		if strings.HasPrefix(proto, "!") {
+			exclude[strings.TrimPrefix(proto, "!")] = true
+		} else {
+			include[proto] = true
+		}
+	}
+	return include, exclude
+}
+
+// networkRouteSingleProtocolFilter returns the numeric protocol to push down
+// as an RT_FILTER_PROTOCOL netlink filter, which is only possible when the
+// configuration names exactly one protocol to include and excludes none.
+func networkRouteSingleProtocolFilter(include, exclude map[string]bool) *uint8 {
+	if len(exclude) > 0 || len(include) != 1 {
+		return nil
+	}
+	for name := range include {
+		if proto, ok := networkRouteProtocolByName(name); ok {
+			return &proto
+		}
+	}
+	return nil
+}
+
+// networkRouteProtocolAllowed applies the include/exclude protocol filters
+// that couldn't be pushed down to the kernel.
+func networkRouteProtocolAllowed(protocol uint8, include, exclude map[string]bool) bool {
+	if len(include) == 0 && len(exclude) == 0 {
+		return true
+	}
+	name := networkRouteProtocolToString(protocol)
+	if exclude[name] {
+		return false
+	}
+	if len(include) > 0 && !include[name] {
+		return false
+	}
+	return true
+}
+
+// networkRouteDeviceAllowed applies the device include/exclude regexes.
+func networkRouteDeviceAllowed(device string, include, exclude *regexp.Regexp) bool {
+	if include != nil && !include.MatchString(device) {
+		return false
+	}
+	if exclude != nil && exclude.MatchString(device) {
+		return false
+	}
+	return true
 }
 
 func networkRouteIPToString(ip net.IP) string {
@@ -212,6 +462,70 @@ func networkRouteProtocolToString(protocol uint8) string {
 	return "unknown"
 }
 
+// networkRouteProtocolByName is the inverse of networkRouteProtocolToString,
+// used to translate a configured protocol name into the numeric value the
+// kernel filters on.
+func networkRouteProtocolByName(name string) (uint8, bool) {
+	for protocol := uint8(0); ; protocol++ {
+		if networkRouteProtocolToString(protocol) == name {
+			return protocol, true
+		}
+		if protocol == 255 {
+			break
+		}
+	}
+	return 0, false
+}
+
+// networkRouteEncapInfo extracts the encap_type, encap_dst and mpls_labels
+// label values for a route. encap and newDst come from the top-level
+// netlink.Route for single-path routes, or from the relevant
+// netlink.NexthopInfo for multipath routes, since MPLS/SEG6 encap on an ECMP
+// route is carried per-nexthop rather than on the route itself. encap_dst
+// carries the MPLS "new destination" (the label(s) a packet is swapped to);
+// mpls_labels carries the MPLS label stack for MPLS encap routes, or the
+// segment list for SEG6 encap routes.
+func networkRouteEncapInfo(encap netlink.Encap, newDst netlink.Destination) (encapType, encapDst, mplsLabels string) {
+	if newDst != nil {
+		encapDst = newDst.String()
+	}
+
+	switch e := encap.(type) {
+	case nil:
+		return "", encapDst, ""
+	case *netlink.MPLSEncap:
+		return "mpls", encapDst, networkRouteJoinMPLSLabels(e.Labels)
+	case *netlink.SEG6Encap:
+		return "seg6", encapDst, networkRouteJoinIPs(e.Segments)
+	default:
+		// IPIP, BPF and other encap types carry no label stack we can
+		// usefully join; fall back to their own string representation.
+		return "unknown", encapDst, encap.String()
+	}
+}
+
+func networkRouteJoinMPLSLabels(labels []int) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = strconv.Itoa(label)
+	}
+	return strings.Join(parts, "/")
+}
+
+func networkRouteJoinIPs(ips []net.IP) string {
+	if len(ips) == 0 {
+		return ""
+	}
+	parts := make([]string, len(ips))
+	for i, ip := range ips {
+		parts[i] = ip.String()
+	}
+	return strings.Join(parts, "/")
+}
+
 func networkRouteDestPrefix(dst *net.IPNet) string {
 	if dst == nil {
 		return "default"
@@ -219,13 +533,29 @@ func networkRouteDestPrefix(dst *net.IPNet) string {
 	return fmt.Sprintf("%s", dst)
 }
 
-func networkRouteGenerateRoutingTableMap(links []netlink.Link) map[int]string {
+// networkRouteBaseTableMap resolves table IDs to names using only the
+// well-known kernel defaults and the /etc/iproute2/rt_tables database. It is
+// shared with the network_fib_trie collector, which has no netlink link list
+// to derive VRF table names from.
+func networkRouteBaseTableMap() map[int]string {
 	rtm := map[int]string{
 		253: "default",
 		254: "main",
 		255: "local",
 	}
 
+	for id, name := range networkRouteParseRTTables(rtTablesPath) {
+		rtm[id] = name
+	}
+
+	return rtm
+}
+
+// networkRouteGenerateRoutingTableMap resolves table IDs to names, combining
+// networkRouteBaseTableMap with any VRF devices discovered on the host.
+func networkRouteGenerateRoutingTableMap(links []netlink.Link) map[int]string {
+	rtm := networkRouteBaseTableMap()
+
 	for _, link := range links {
 		linkType := link.Type()
 		if linkType == "vrf" {
@@ -236,3 +566,221 @@ func networkRouteGenerateRoutingTableMap(links []netlink.Link) map[int]string {
 
 	return rtm
 }
+
+// networkRouteParseRTTables parses an iproute2 rt_tables file ("id name" per
+// line, '#' comments). A missing file is not an error: it simply contributes
+// no additional table names.
+func networkRouteParseRTTables(path string) map[int]string {
+	rtm := map[int]string{}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return rtm
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		rtm[id] = fields[1]
+	}
+
+	return rtm
+}
+
+// networkRouteCacheInfoEntry is the subset of an RTM_GETROUTE RTA_CACHEINFO
+// attribute that collector.network_route.cache-info reports.
+type networkRouteCacheInfoEntry struct {
+	device  string
+	dest    string
+	table   string
+	family  string
+	expires float64
+	lastUse float64
+	errno   float64
+}
+
+// nativeEndian is the host's native byte order. Netlink messages are
+// exchanged with the local kernel only, so they are always encoded in the
+// sender's native order rather than network byte order.
+var nativeEndian = func() binary.ByteOrder {
+	var i int32 = 0x01020304
+	if *(*byte)(unsafe.Pointer(&i)) == 0x04 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// networkRouteCacheInfoGet dumps RTA_CACHEINFO for every route the kernel
+// reports it for. It isn't exposed on netlink.Route by the netlink library
+// this collector otherwise uses, so it's fetched with a hand-rolled
+// RTM_GETROUTE dump instead.
+func networkRouteCacheInfoGet(links []netlink.Link, routingTableMaps map[int]string) ([]networkRouteCacheInfoEntry, error) {
+	var entries []networkRouteCacheInfoEntry
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		famEntries, err := networkRouteCacheInfoDump(family, links, routingTableMaps)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, famEntries...)
+	}
+	return entries, nil
+}
+
+// networkRouteCacheInfoDump performs a single RTM_GETROUTE/NLM_F_DUMP request
+// for the given address family and extracts the RTA_CACHEINFO attribute of
+// every route the kernel includes one for.
+func networkRouteCacheInfoDump(family uint8, links []netlink.Link, routingTableMaps map[int]string) ([]networkRouteCacheInfoEntry, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW, unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open netlink socket: %w", err)
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("couldn't bind netlink socket: %w", err)
+	}
+
+	req := networkRouteCacheInfoRequest(family)
+	if err := unix.Sendto(fd, req, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("couldn't send netlink request: %w", err)
+	}
+
+	var entries []networkRouteCacheInfoEntry
+	buf := make([]byte, unix.Getpagesize())
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read netlink response: %w", err)
+		}
+
+		msgs, err := unix.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse netlink message: %w", err)
+		}
+
+		done := false
+		for _, msg := range msgs {
+			switch msg.Header.Type {
+			case unix.NLMSG_DONE:
+				done = true
+			case unix.NLMSG_ERROR:
+				return nil, fmt.Errorf("netlink error response dumping route cache info for family %d", family)
+			case unix.RTM_NEWROUTE:
+				if entry, ok := networkRouteCacheInfoParse(msg, links, routingTableMaps); ok {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+// networkRouteCacheInfoRequest builds a minimal RTM_GETROUTE/NLM_F_DUMP
+// request: a netlink header followed by an rtmsg with only Family set, which
+// is sufficient to ask the kernel to dump every route of that family.
+func networkRouteCacheInfoRequest(family uint8) []byte {
+	buf := make([]byte, unix.SizeofNlMsghdr+unix.SizeofRtMsg)
+
+	nativeEndian.PutUint32(buf[0:4], uint32(len(buf)))
+	nativeEndian.PutUint16(buf[4:6], unix.RTM_GETROUTE)
+	nativeEndian.PutUint16(buf[6:8], unix.NLM_F_REQUEST|unix.NLM_F_DUMP)
+	nativeEndian.PutUint32(buf[8:12], 1)
+	nativeEndian.PutUint32(buf[12:16], 0)
+
+	buf[unix.SizeofNlMsghdr] = family // rtmsg.Family
+
+	return buf
+}
+
+// networkRouteCacheInfoParse extracts a networkRouteCacheInfoEntry out of a
+// single RTM_NEWROUTE message, reporting ok=false for routes the kernel
+// didn't attach an RTA_CACHEINFO attribute to (most routes on a modern
+// kernel: it's now mainly IPv6 and redirected/PMTU-discovered routes).
+func networkRouteCacheInfoParse(msg unix.NetlinkMessage, links []netlink.Link, routingTableMaps map[int]string) (networkRouteCacheInfoEntry, bool) {
+	if len(msg.Data) < unix.SizeofRtMsg {
+		return networkRouteCacheInfoEntry{}, false
+	}
+
+	family := msg.Data[0]
+	dstLen := msg.Data[1]
+	table := int(msg.Data[4])
+
+	attrs, err := unix.ParseNetlinkRouteAttr(&unix.NetlinkMessage{
+		Header: msg.Header,
+		Data:   msg.Data[unix.SizeofRtMsg:],
+	})
+	if err != nil {
+		return networkRouteCacheInfoEntry{}, false
+	}
+
+	var (
+		dst       net.IP
+		oif       int
+		cacheInfo []byte
+	)
+	for _, attr := range attrs {
+		switch int(attr.Attr.Type) {
+		case unix.RTA_DST:
+			dst = net.IP(attr.Value)
+		case unix.RTA_OIF:
+			oif = int(nativeEndian.Uint32(attr.Value))
+		case unix.RTA_TABLE:
+			table = int(nativeEndian.Uint32(attr.Value))
+		case unix.RTA_CACHEINFO:
+			cacheInfo = attr.Value
+		}
+	}
+	// struct rta_cacheinfo: clntref, lastuse, expires, error, used (5 x u32).
+	if len(cacheInfo) < 20 {
+		return networkRouteCacheInfoEntry{}, false
+	}
+
+	device := ""
+	for _, link := range links {
+		if link.Attrs().Index == oif {
+			device = link.Attrs().Name
+			break
+		}
+	}
+
+	dest := "default"
+	if dst != nil {
+		dest = (&net.IPNet{IP: dst, Mask: net.CIDRMask(int(dstLen), len(dst)*8)}).String()
+	}
+
+	return networkRouteCacheInfoEntry{
+		device:  device,
+		dest:    dest,
+		table:   routingTableMaps[table],
+		family:  networkRouteCacheInfoFamilyName(family),
+		lastUse: float64(nativeEndian.Uint32(cacheInfo[4:8])) / clockTicksPerSecond,
+		expires: float64(int32(nativeEndian.Uint32(cacheInfo[8:12]))) / clockTicksPerSecond,
+		errno:   float64(int32(nativeEndian.Uint32(cacheInfo[12:16]))),
+	}, true
+}
+
+func networkRouteCacheInfoFamilyName(family uint8) string {
+	switch int(family) {
+	case netlink.FAMILY_V4:
+		return "IPv4"
+	case netlink.FAMILY_V6:
+		return "IPv6"
+	}
+	return "unknown"
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetworkroute
+// +build !nonetworkroute
+
+package collector
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+)
+
+func TestNetworkRouteEncapInfoNoEncap(t *testing.T) {
+	encapType, encapDst, mplsLabels := networkRouteEncapInfo(nil, nil)
+	if encapType != "" || encapDst != "" || mplsLabels != "" {
+		t.Errorf("expected empty encap info for a route without encap, got (%q, %q, %q)", encapType, encapDst, mplsLabels)
+	}
+}
+
+func TestNetworkRouteEncapInfoMPLS(t *testing.T) {
+	encap := &netlink.MPLSEncap{Labels: []int{100, 200, 300}}
+
+	encapType, _, mplsLabels := networkRouteEncapInfo(encap, nil)
+	if encapType != "mpls" {
+		t.Errorf("expected encap_type %q, got %q", "mpls", encapType)
+	}
+	if mplsLabels != "100/200/300" {
+		t.Errorf("expected mpls_labels %q, got %q", "100/200/300", mplsLabels)
+	}
+}
+
+func TestNetworkRouteEncapInfoSEG6(t *testing.T) {
+	encap := &netlink.SEG6Encap{
+		Segments: []net.IP{
+			net.ParseIP("2001:db8::1"),
+			net.ParseIP("2001:db8::2"),
+		},
+	}
+
+	encapType, _, mplsLabels := networkRouteEncapInfo(encap, nil)
+	if encapType != "seg6" {
+		t.Errorf("expected encap_type %q, got %q", "seg6", encapType)
+	}
+	want := "2001:db8::1/2001:db8::2"
+	if mplsLabels != want {
+		t.Errorf("expected mpls_labels %q, got %q", want, mplsLabels)
+	}
+}
+
+// TestNetworkRouteEncapInfoMultipathNexthop covers the SR-MPLS ECMP case:
+// encap on a multipath route is carried per-nexthop, not on the route
+// itself, so the top-level route's (nil) encap must not shadow it.
+func TestNetworkRouteEncapInfoMultipathNexthop(t *testing.T) {
+	route := netlink.Route{
+		MultiPath: []*netlink.NexthopInfo{
+			{Encap: &netlink.MPLSEncap{Labels: []int{16}}},
+			{Encap: &netlink.MPLSEncap{Labels: []int{100, 200}}},
+		},
+	}
+
+	encapType, _, mplsLabels := networkRouteEncapInfo(route.Encap, route.NewDst)
+	if encapType != "" || mplsLabels != "" {
+		t.Errorf("expected the route-level encap to be empty for a multipath route, got (%q, %q)", encapType, mplsLabels)
+	}
+
+	for i, want := range []string{"16", "100/200"} {
+		nextHop := route.MultiPath[i]
+		encapType, _, mplsLabels := networkRouteEncapInfo(nextHop.Encap, nextHop.NewDst)
+		if encapType != "mpls" {
+			t.Errorf("nexthop %d: expected encap_type %q, got %q", i, "mpls", encapType)
+		}
+		if mplsLabels != want {
+			t.Errorf("nexthop %d: expected mpls_labels %q, got %q", i, want, mplsLabels)
+		}
+	}
+}
+
+func TestNetworkRouteJoinMPLSLabels(t *testing.T) {
+	if got := networkRouteJoinMPLSLabels(nil); got != "" {
+		t.Errorf("expected empty string for nil labels, got %q", got)
+	}
+	if got := networkRouteJoinMPLSLabels([]int{16}); got != "16" {
+		t.Errorf("expected %q, got %q", "16", got)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetworkrouterule
+// +build !nonetworkrouterule
+
+package collector
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+type networkRouteRuleCollector struct {
+	ruleInfoDesc *prometheus.Desc
+	logger       log.Logger
+}
+
+func init() {
+	registerCollector("network_route_rule", defaultDisabled, NewNetworkRouteRuleCollector)
+}
+
+// NewNetworkRouteRuleCollector returns a new Collector exposing policy
+// routing rules (RTM_GETRULE). It complements network_route, which alone
+// cannot explain why a given route was selected on hosts using VRFs,
+// multiple routing tables, or policy-based routing.
+func NewNetworkRouteRuleCollector(logger log.Logger) (Collector, error) {
+	const subsystem = "network"
+
+	ruleInfoDesc := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "route_rule_info"),
+		"network routing policy rule information",
+		[]string{"family", "priority", "table", "iif", "oif", "src", "dest", "fwmark", "fwmask", "tos", "action", "suppress_prefixlen"}, nil,
+	)
+
+	return &networkRouteRuleCollector{
+		ruleInfoDesc: ruleInfoDesc,
+		logger:       logger,
+	}, nil
+}
+
+func (n networkRouteRuleCollector) Update(ch chan<- prometheus.Metric) error {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return fmt.Errorf("couldn't get links: %w", err)
+	}
+	routingTableMaps := networkRouteGenerateRoutingTableMap(links)
+
+	rules, err := netlink.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		return fmt.Errorf("couldn't get rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		labels := []string{
+			networkRouteFamilyToString(rule.Family),             // family
+			strconv.Itoa(rule.Priority),                         // priority
+			routingTableMaps[rule.Table],                        // table
+			rule.IifName,                                        // iif
+			rule.OifName,                                        // oif
+			networkRouteRuleIPNetToString(rule.Src),             // src
+			networkRouteRuleIPNetToString(rule.Dst),             // dest
+			strconv.FormatUint(uint64(rule.Mark), 10),           // fwmark
+			networkRouteRuleMaskToString(rule.Mask),             // fwmask
+			strconv.Itoa(int(rule.Tos)),                         // tos
+			networkRouteRuleActionToString(rule.Type),           // action
+			networkRouteRuleIntToString(rule.SuppressPrefixlen), // suppress_prefixlen
+		}
+		ch <- prometheus.MustNewConstMetric(n.ruleInfoDesc, prometheus.GaugeValue, 1, labels...)
+	}
+
+	return nil
+}
+
+func networkRouteFamilyToString(family int) string {
+	switch family {
+	case netlink.FAMILY_V4:
+		return "IPv4"
+	case netlink.FAMILY_V6:
+		return "IPv6"
+	}
+	return "unknown"
+}
+
+func networkRouteRuleIPNetToString(ipnet *net.IPNet) string {
+	if ipnet == nil {
+		return ""
+	}
+	return ipnet.String()
+}
+
+// networkRouteRuleMaskToString formats the fwmask rule field, which netlink
+// leaves nil when the rule has no FRA_FWMASK attribute.
+func networkRouteRuleMaskToString(mask *uint32) string {
+	if mask == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*mask), 10)
+}
+
+// networkRouteRuleIntToString formats a rule field that netlink leaves at -1
+// when the kernel didn't report a value for it (e.g. no
+// FRA_SUPPRESS_PREFIXLEN attribute), emitting an empty string rather than
+// the literal sentinel.
+func networkRouteRuleIntToString(v int) string {
+	if v == -1 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+func networkRouteRuleActionToString(action uint8) string {
+	// from linux kernel 'include/uapi/linux/fib_rules.h'
+	switch int(action) {
+	case unix.FR_ACT_TO_TBL:
+		return "to_tbl"
+	case unix.FR_ACT_GOTO:
+		return "goto"
+	case unix.FR_ACT_NOP:
+		return "nop"
+	case unix.FR_ACT_BLACKHOLE:
+		return "blackhole"
+	case unix.FR_ACT_UNREACHABLE:
+		return "unreachable"
+	case unix.FR_ACT_PROHIBIT:
+		return "prohibit"
+	}
+	return "unknown"
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetworkrouterule
+// +build !nonetworkrouterule
+
+package collector
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestNetworkRouteFamilyToString(t *testing.T) {
+	tests := []struct {
+		name   string
+		family int
+		want   string
+	}{
+		{"ipv4", netlink.FAMILY_V4, "IPv4"},
+		{"ipv6", netlink.FAMILY_V6, "IPv6"},
+		{"unknown", 9999, "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := networkRouteFamilyToString(tt.family); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkRouteRuleIPNetToString(t *testing.T) {
+	if got := networkRouteRuleIPNetToString(nil); got != "" {
+		t.Errorf("expected empty string for nil ipnet, got %q", got)
+	}
+
+	_, ipnet, _ := net.ParseCIDR("10.0.0.0/8")
+	if got, want := networkRouteRuleIPNetToString(ipnet), "10.0.0.0/8"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNetworkRouteRuleMaskToString(t *testing.T) {
+	if got := networkRouteRuleMaskToString(nil); got != "" {
+		t.Errorf("expected empty string for a nil mask, got %q", got)
+	}
+
+	mask := uint32(0xff)
+	if got, want := networkRouteRuleMaskToString(&mask), "255"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNetworkRouteRuleIntToString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   int
+		want string
+	}{
+		{"unset sentinel", -1, ""},
+		{"zero", 0, "0"},
+		{"positive", 24, "24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := networkRouteRuleIntToString(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkRouteRuleActionToString(t *testing.T) {
+	tests := []struct {
+		name   string
+		action uint8
+		want   string
+	}{
+		{"to_tbl", uint8(unix.FR_ACT_TO_TBL), "to_tbl"},
+		{"goto", uint8(unix.FR_ACT_GOTO), "goto"},
+		{"nop", uint8(unix.FR_ACT_NOP), "nop"},
+		{"blackhole", uint8(unix.FR_ACT_BLACKHOLE), "blackhole"},
+		{"unreachable", uint8(unix.FR_ACT_UNREACHABLE), "unreachable"},
+		{"prohibit", uint8(unix.FR_ACT_PROHIBIT), "prohibit"},
+		{"unknown", 255, "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := networkRouteRuleActionToString(tt.action); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nowireguard
+// +build !nowireguard
+
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+	"golang.zx2c4.com/wireguard/wgctrl"
+)
+
+type wireguardCollector struct {
+	deviceInfoDesc       *prometheus.Desc
+	peerEndpointInfoDesc *prometheus.Desc
+	peerLastHandshake    *prometheus.Desc
+	peerReceiveBytes     *prometheus.Desc
+	peerTransmitBytes    *prometheus.Desc
+	logger               log.Logger
+}
+
+func init() {
+	registerCollector("wireguard", defaultDisabled, NewWireguardCollector)
+}
+
+// NewWireguardCollector returns a new Collector exposing WireGuard device and
+// peer statistics.
+func NewWireguardCollector(logger log.Logger) (Collector, error) {
+	const subsystem = "wireguard"
+
+	return &wireguardCollector{
+		deviceInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "device_info"),
+			"WireGuard device information", []string{"device", "public_key", "listen_port", "fwmark"}, nil,
+		),
+		peerEndpointInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "peer_endpoint_info"),
+			"WireGuard peer endpoint information", []string{"device", "public_key", "endpoint", "allowed_ips"}, nil,
+		),
+		peerLastHandshake: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "peer_last_handshake_seconds"),
+			"Time of the last completed handshake with a peer, as a Unix timestamp", []string{"device", "public_key"}, nil,
+		),
+		peerReceiveBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "peer_receive_bytes_total"),
+			"Bytes received from a peer", []string{"device", "public_key"}, nil,
+		),
+		peerTransmitBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "peer_transmit_bytes_total"),
+			"Bytes transmitted to a peer", []string{"device", "public_key"}, nil,
+		),
+		logger: logger,
+	}, nil
+}
+
+func (c *wireguardCollector) Update(ch chan<- prometheus.Metric) error {
+	client, err := wgctrl.New()
+	if err != nil {
+		// No WireGuard kernel module and no userspace implementation
+		// found on this host; nothing to report.
+		return ErrNoData
+	}
+	defer client.Close()
+
+	devices, err := client.Devices()
+	if err != nil {
+		return fmt.Errorf("couldn't get wireguard devices: %w", err)
+	}
+	if len(devices) == 0 {
+		return ErrNoData
+	}
+
+	// Cross-check devices against the host's link list so the device
+	// label matches exactly what the network_route collector reports for
+	// the same interface.
+	links, err := netlink.LinkList()
+	if err != nil {
+		return fmt.Errorf("couldn't get links: %w", err)
+	}
+	knownLinks := make(map[string]bool, len(links))
+	for _, link := range links {
+		knownLinks[link.Attrs().Name] = true
+	}
+
+	for _, device := range devices {
+		if !knownLinks[device.Name] {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.deviceInfoDesc, prometheus.GaugeValue, 1,
+			device.Name, device.PublicKey.String(), strconv.Itoa(device.ListenPort), strconv.Itoa(device.FirewallMark))
+
+		for _, peer := range device.Peers {
+			publicKey := peer.PublicKey.String()
+
+			endpoint := ""
+			if peer.Endpoint != nil {
+				endpoint = peer.Endpoint.String()
+			}
+			allowedIPs := make([]string, len(peer.AllowedIPs))
+			for i, ip := range peer.AllowedIPs {
+				allowedIPs[i] = ip.String()
+			}
+
+			var lastHandshake float64
+			if !peer.LastHandshakeTime.IsZero() {
+				lastHandshake = float64(peer.LastHandshakeTime.Unix())
+			}
+
+			ch <- prometheus.MustNewConstMetric(c.peerEndpointInfoDesc, prometheus.GaugeValue, 1,
+				device.Name, publicKey, endpoint, strings.Join(allowedIPs, ","))
+			ch <- prometheus.MustNewConstMetric(c.peerLastHandshake, prometheus.GaugeValue,
+				lastHandshake, device.Name, publicKey)
+			ch <- prometheus.MustNewConstMetric(c.peerReceiveBytes, prometheus.CounterValue,
+				float64(peer.ReceiveBytes), device.Name, publicKey)
+			ch <- prometheus.MustNewConstMetric(c.peerTransmitBytes, prometheus.CounterValue,
+				float64(peer.TransmitBytes), device.Name, publicKey)
+		}
+	}
+
+	return nil
+}